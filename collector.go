@@ -0,0 +1,65 @@
+package metrics
+
+// Collector is implemented by types that expose metrics computed on demand
+// from an external source - for example a job scheduler that tracks its
+// own per-job counters and wants to surface them without pre-registering
+// one metric per job. A Collector registered with a Registry via
+// RegisterCollector is asked, on every Each, to emit its current metrics
+// by calling the function it is given.
+type Collector interface {
+	Collect(func(name string, metric interface{}))
+}
+
+// CollectorFunc adapts a plain function to a Collector.
+type CollectorFunc func(func(name string, metric interface{}))
+
+// Collect calls f.
+func (f CollectorFunc) Collect(emit func(name string, metric interface{})) {
+	f(emit)
+}
+
+// NewFuncCounter constructs a Counter whose Count() calls f on every read.
+// It is meant for pull-based values a caller already tracks itself: Clear,
+// Inc, and Dec are no-ops since f, not the Counter, owns the value.
+func NewFuncCounter(f func() int64) Counter {
+	return &funcCounter{value: f}
+}
+
+type funcCounter struct {
+	value func() int64
+}
+
+// Clear is a no-op.
+func (c *funcCounter) Clear() {}
+
+// Count calls the underlying function.
+func (c *funcCounter) Count() int64 { return c.value() }
+
+// Dec is a no-op.
+func (c *funcCounter) Dec(int64) {}
+
+// Inc is a no-op.
+func (c *funcCounter) Inc(int64) {}
+
+// Snapshot returns a read-only copy of the counter's current value.
+func (c *funcCounter) Snapshot() Counter { return CounterSnapshot(c.value()) }
+
+// NewFuncGauge constructs a GaugeFloat64 whose Value() calls f on every
+// read. It is meant for pull-based values a caller already tracks itself:
+// Update is a no-op since f, not the GaugeFloat64, owns the value.
+func NewFuncGauge(f func() float64) GaugeFloat64 {
+	return &funcGauge{value: f}
+}
+
+type funcGauge struct {
+	value func() float64
+}
+
+// Snapshot returns a read-only copy of the gauge's current value.
+func (g *funcGauge) Snapshot() GaugeFloat64 { return GaugeFloat64Snapshot(g.value()) }
+
+// Update is a no-op.
+func (g *funcGauge) Update(float64) {}
+
+// Value calls the underlying function.
+func (g *funcGauge) Value() float64 { return g.value() }
@@ -0,0 +1,117 @@
+package metrics
+
+import "testing"
+
+func TestRegistryCollector(t *testing.T) {
+	r := NewRegistry()
+	r.Register("static", NewCounter())
+
+	jobs := map[string]int64{"job-a": 3, "job-b": 7}
+	r.RegisterCollector(CollectorFunc(func(emit func(string, interface{})) {
+		for name, count := range jobs {
+			emit(name, NewFuncCounter(func() int64 { return count }))
+		}
+	}))
+
+	seen := map[string]bool{}
+	r.Each(func(name string, _ interface{}) {
+		seen[name] = true
+	})
+
+	for _, name := range []string{"static", "job-a", "job-b"} {
+		if !seen[name] {
+			t.Fatalf("expected %q to be yielded by Each, got %v", name, seen)
+		}
+	}
+}
+
+func TestRegistryCollectorDistinctClosuresFromSameLiteral(t *testing.T) {
+	r := NewRegistry()
+	jobs := []string{"job-a", "job-b", "job-c"}
+	for _, job := range jobs {
+		job := job
+		r.RegisterCollector(CollectorFunc(func(emit func(string, interface{})) {
+			emit(job, NewFuncCounter(func() int64 { return 1 }))
+		}))
+	}
+
+	seen := map[string]bool{}
+	r.Each(func(name string, _ interface{}) {
+		seen[name] = true
+	})
+
+	for _, job := range jobs {
+		if !seen[job] {
+			t.Fatalf("expected %q to be yielded by Each, got %v", job, seen)
+		}
+	}
+}
+
+func TestUnregisterCollector(t *testing.T) {
+	r := NewRegistry()
+	collect := CollectorFunc(func(emit func(string, interface{})) {
+		emit("dynamic", NewFuncCounter(func() int64 { return 1 }))
+	})
+	handle := r.RegisterCollector(collect)
+
+	i := 0
+	r.Each(func(string, interface{}) { i++ })
+	if i != 1 {
+		t.Fatal(i)
+	}
+
+	r.UnregisterCollector(handle)
+	i = 0
+	r.Each(func(string, interface{}) { i++ })
+	if i != 0 {
+		t.Fatal(i)
+	}
+}
+
+func TestUnregisterCollectorByHandleRemovesOnlyTheTargetedRegistration(t *testing.T) {
+	r := NewRegistry()
+	jobs := []string{"job-a", "job-b", "job-c"}
+	handles := make([]CollectorHandle, len(jobs))
+	for i, job := range jobs {
+		job := job
+		handles[i] = r.RegisterCollector(CollectorFunc(func(emit func(string, interface{})) {
+			emit(job, NewFuncCounter(func() int64 { return 1 }))
+		}))
+	}
+
+	r.UnregisterCollector(handles[1])
+
+	seen := map[string]bool{}
+	r.Each(func(name string, _ interface{}) {
+		seen[name] = true
+	})
+
+	if seen["job-b"] {
+		t.Fatalf("expected job-b to be unregistered, got %v", seen)
+	}
+	if !seen["job-a"] || !seen["job-c"] {
+		t.Fatalf("expected job-a and job-c to remain registered, got %v", seen)
+	}
+}
+
+func TestNewFuncCounterAndGauge(t *testing.T) {
+	jobCount := int64(5)
+	c := NewFuncCounter(func() int64 { return jobCount })
+	if count := c.Count(); count != 5 {
+		t.Fatal(count)
+	}
+	c.Inc(10) // no-op: value is owned by the function, not the Counter
+	if count := c.Count(); count != 5 {
+		t.Fatal(count)
+	}
+
+	load := 0.5
+	g := NewFuncGauge(func() float64 { return load })
+	if value := g.Value(); value != 0.5 {
+		t.Fatal(value)
+	}
+	load = 1.5
+	if value := g.Value(); value != 1.5 {
+		t.Fatal(value)
+	}
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Meter counts events to produce exponentially-weighted moving average
+// rates at one-, five-, and fifteen-minutes and a mean rate. It is the
+// write side of the metric: call Mark to record events and Snapshot to
+// obtain an immutable, internally-consistent view of the rates for
+// reading, since Rate1/Rate5/Rate15/RateMean/Count are derived together on
+// every tick and reading them one at a time off a live Meter could observe
+// them mid-update.
+type Meter interface {
+	Mark(int64)
+	Snapshot() MeterSnapshot
+	Stop()
+}
+
+// MeterSnapshot is a read-only view of a Meter's rates and count at the
+// moment Snapshot() was called.
+type MeterSnapshot interface {
+	Count() int64
+	Rate1() float64
+	Rate5() float64
+	Rate15() float64
+	RateMean() float64
+}
+
+// GetOrRegisterMeter returns an existing Meter or constructs and registers a
+// new StandardMeter.
+func GetOrRegisterMeter(name string, r Registry) Meter {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, NewMeter).(Meter)
+}
+
+// NewMeter constructs a new StandardMeter and launches a goroutine.
+func NewMeter() Meter {
+	if UseNilMetrics {
+		return NilMeter{}
+	}
+	m := newStandardMeter()
+	arbiter.Lock()
+	defer arbiter.Unlock()
+	arbiter.meters[m] = struct{}{}
+	if !arbiter.started {
+		arbiter.started = true
+		go arbiter.tick()
+	}
+	return m
+}
+
+// NewRegisteredMeter constructs and registers a new StandardMeter.
+func NewRegisteredMeter(name string, r Registry) Meter {
+	c := NewMeter()
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// meterSnapshot is the standard implementation of MeterSnapshot.
+type meterSnapshot struct {
+	count                          int64
+	rate1, rate5, rate15, rateMean float64
+}
+
+// Count returns the count of events at the time the snapshot was taken.
+func (m *meterSnapshot) Count() int64 { return m.count }
+
+// Rate1 returns the one-minute moving average rate of events per second at
+// the time the snapshot was taken.
+func (m *meterSnapshot) Rate1() float64 { return m.rate1 }
+
+// Rate5 returns the five-minute moving average rate of events per second at
+// the time the snapshot was taken.
+func (m *meterSnapshot) Rate5() float64 { return m.rate5 }
+
+// Rate15 returns the fifteen-minute moving average rate of events per second
+// at the time the snapshot was taken.
+func (m *meterSnapshot) Rate15() float64 { return m.rate15 }
+
+// RateMean returns the meter's mean rate of events per second at the time
+// the snapshot was taken.
+func (m *meterSnapshot) RateMean() float64 { return m.rateMean }
+
+// NilMeter is a no-op Meter.
+type NilMeter struct{}
+
+// Mark is a no-op.
+func (NilMeter) Mark(n int64) {}
+
+// Snapshot returns a MeterSnapshot whose rates are always zero.
+func (NilMeter) Snapshot() MeterSnapshot { return &meterSnapshot{} }
+
+// Stop is a no-op.
+func (NilMeter) Stop() {}
+
+// StandardMeter is the standard implementation of a Meter.
+type StandardMeter struct {
+	lock        sync.RWMutex
+	snapshot    *meterSnapshot
+	a1, a5, a15 EWMA
+	startTime   time.Time
+	stopped     bool
+}
+
+func newStandardMeter() *StandardMeter {
+	return &StandardMeter{
+		snapshot:  &meterSnapshot{},
+		a1:        NewEWMA1(),
+		a5:        NewEWMA5(),
+		a15:       NewEWMA15(),
+		startTime: time.Now(),
+	}
+}
+
+// Stop stops the meter, Mark() will be a no-op for it afterwards.
+func (m *StandardMeter) Stop() {
+	m.lock.Lock()
+	stopped := m.stopped
+	m.stopped = true
+	m.lock.Unlock()
+	if !stopped {
+		arbiter.Lock()
+		delete(arbiter.meters, m)
+		arbiter.Unlock()
+	}
+}
+
+// Mark records the occurrence of n events.
+func (m *StandardMeter) Mark(n int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stopped {
+		return
+	}
+	m.snapshot.count += n
+	m.a1.Update(n)
+	m.a5.Update(n)
+	m.a15.Update(n)
+	m.updateSnapshot()
+}
+
+// Snapshot returns a read-only, internally-consistent copy of the meter's
+// count and rates.
+func (m *StandardMeter) Snapshot() MeterSnapshot {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	snapshot := *m.snapshot
+	return &snapshot
+}
+
+func (m *StandardMeter) updateSnapshot() {
+	snapshot := m.snapshot
+	snapshot.rate1 = m.a1.Rate()
+	snapshot.rate5 = m.a5.Rate()
+	snapshot.rate15 = m.a15.Rate()
+	snapshot.rateMean = float64(snapshot.count) / time.Since(m.startTime).Seconds()
+}
+
+func (m *StandardMeter) tick() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.a1.Tick()
+	m.a5.Tick()
+	m.a15.Tick()
+	m.updateSnapshot()
+}
+
+type meterArbiter struct {
+	sync.RWMutex
+	started bool
+	meters  map[*StandardMeter]struct{}
+	ticker  *time.Ticker
+}
+
+var arbiter = meterArbiter{ticker: time.NewTicker(5 * time.Second), meters: make(map[*StandardMeter]struct{})}
+
+// tick meters on the scheduled interval.
+func (ma *meterArbiter) tick() {
+	for range ma.ticker.C {
+		ma.tickMeters()
+	}
+}
+
+func (ma *meterArbiter) tickMeters() {
+	ma.RLock()
+	defer ma.RUnlock()
+	for meter := range ma.meters {
+		meter.tick()
+	}
+}
@@ -0,0 +1,8 @@
+// Package metrics provides general purpose instrumentation of Go code
+// compatible with metrics-instrumented code in other languages, notably
+// http://metrics.codahale.com/.
+//
+// Counters, Gauges, Histograms, Meters and Timers may be registered in a
+// Registry and read back through its Each method by a custom reporter; the
+// package itself includes no reporter.
+package metrics
@@ -0,0 +1,397 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DuplicateMetric is the error returned by Registry.Register when a metric
+// already exists under the given name.
+type DuplicateMetric string
+
+func (err DuplicateMetric) Error() string {
+	return fmt.Sprintf("duplicate metric: %s", string(err))
+}
+
+// Registry holds references to a set of metrics by name and can iterate
+// over them, calling callback functions provided by the user.
+//
+// This is an interface so as to encourage other structs to implement the
+// Registry API as appropriate.
+type Registry interface {
+	// Call the given function for each registered metric.
+	Each(func(string, interface{}))
+
+	// Get the metric by the given name or nil if none is registered.
+	Get(string) interface{}
+
+	// Gets an existing metric or registers the given one. The interface can
+	// be the metric to register if not found in registry, or a function
+	// returning the metric for lazy instantiation.
+	GetOrRegister(string, interface{}) interface{}
+
+	// Register the given metric under the given name.
+	Register(string, interface{}) error
+
+	// Unregister the metric with the given name.
+	Unregister(string)
+
+	// Unregister all metrics. (Mostly for testing.)
+	UnregisterAll()
+
+	// RegisterCollector adds a Collector that Each will query for
+	// dynamically-computed metrics on every call, and returns a handle
+	// identifying this registration.
+	RegisterCollector(Collector) CollectorHandle
+
+	// UnregisterCollector removes the Collector registration identified
+	// by the given handle.
+	UnregisterCollector(CollectorHandle)
+}
+
+// CollectorHandle identifies a single RegisterCollector call, so that
+// UnregisterCollector can remove exactly that registration. A Collector is
+// frequently func-backed (CollectorFunc), and distinct closures built from
+// the same literal are not guaranteed distinct identities (equality on
+// func-kind values even panics), so handles - not Collector values - are
+// the only reliable way to unregister a specific registration.
+type CollectorHandle struct {
+	id uint64
+}
+
+var collectorHandleSeq uint64
+
+func newCollectorHandle() CollectorHandle {
+	return CollectorHandle{id: atomic.AddUint64(&collectorHandleSeq, 1)}
+}
+
+// StandardRegistry is the standard implementation of a Registry. It is
+// backed by a sync.Map so that Get, GetOrRegister, Register, Unregister,
+// and Each scale to high-cardinality sets of metrics without serializing
+// readers and writers behind a single mutex. Collectors are expected to be
+// few in number, so they are kept in a plain mutex-guarded slice instead.
+type StandardRegistry struct {
+	metrics      sync.Map
+	collectorsMu sync.Mutex
+	collectors   []collectorEntry
+}
+
+// collectorEntry pairs a registered Collector with the handle it was
+// registered under.
+type collectorEntry struct {
+	handle CollectorHandle
+	c      Collector
+}
+
+// NewRegistry constructs a new StandardRegistry.
+func NewRegistry() Registry {
+	return &StandardRegistry{}
+}
+
+// Each calls the given function for each registered metric, then for each
+// metric yielded by a registered Collector.
+func (r *StandardRegistry) Each(f func(string, interface{})) {
+	r.metrics.Range(func(key, value interface{}) bool {
+		f(key.(string), unwrapLazyMetric(value))
+		return true
+	})
+	for _, entry := range r.snapshotCollectors() {
+		entry.c.Collect(f)
+	}
+}
+
+// snapshotCollectors returns a copy of the registered collectors, safe to
+// range over without holding collectorsMu.
+func (r *StandardRegistry) snapshotCollectors() []collectorEntry {
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	collectors := make([]collectorEntry, len(r.collectors))
+	copy(collectors, r.collectors)
+	return collectors
+}
+
+// RegisterCollector adds a Collector that Each will query for
+// dynamically-computed metrics on every call, and returns a handle
+// identifying this registration. Every call appends a new entry, so
+// registering the same CollectorFunc literal multiple times (e.g. once
+// per job in a loop) adds one entry per call instead of later calls
+// overwriting earlier ones.
+func (r *StandardRegistry) RegisterCollector(c Collector) CollectorHandle {
+	handle := newCollectorHandle()
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	r.collectors = append(r.collectors, collectorEntry{handle: handle, c: c})
+	return handle
+}
+
+// UnregisterCollector removes the Collector registration identified by
+// handle, leaving any other registration of the same (or an
+// indistinguishable) Collector value untouched.
+func (r *StandardRegistry) UnregisterCollector(handle CollectorHandle) {
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	for i, entry := range r.collectors {
+		if entry.handle == handle {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get the metric by the given name or nil if none is registered.
+func (r *StandardRegistry) Get(name string) interface{} {
+	item, ok := r.metrics.Load(name)
+	if !ok {
+		return nil
+	}
+	return unwrapLazyMetric(item)
+}
+
+// GetOrRegister gets an existing metric or registers the given one. The
+// interface can be the metric to register if not found in registry, or a
+// function returning the metric for lazy instantiation. The constructor is
+// invoked at most once even if multiple goroutines race to register the
+// same name, because only one of the lazyMetric wrappers they create wins
+// the LoadOrStore and every caller then forces the winner's sync.Once.
+func (r *StandardRegistry) GetOrRegister(name string, i interface{}) interface{} {
+	if item, ok := r.metrics.Load(name); ok {
+		return unwrapLazyMetric(item)
+	}
+	lazy := &lazyMetric{construct: constructorFor(i)}
+	actual, _ := r.metrics.LoadOrStore(name, lazy)
+	return unwrapLazyMetric(actual)
+}
+
+// Register the given metric under the given name. If i is a function
+// returning the metric to register, it is invoked at most once, and only
+// after name is confirmed not already registered: constructing eagerly
+// before that check would leak a metric (e.g. a Meter running its
+// arbiter goroutine) that no caller ever obtains a handle to stop.
+func (r *StandardRegistry) Register(name string, i interface{}) error {
+	if _, loaded := r.metrics.Load(name); loaded {
+		return DuplicateMetric(name)
+	}
+	lazy := &lazyMetric{construct: constructorFor(i)}
+	if _, loaded := r.metrics.LoadOrStore(name, lazy); loaded {
+		return DuplicateMetric(name)
+	}
+	return nil
+}
+
+// Unregister the metric with the given name.
+func (r *StandardRegistry) Unregister(name string) {
+	item, loaded := r.metrics.LoadAndDelete(name)
+	if !loaded {
+		return
+	}
+	stop(unwrapLazyMetric(item))
+}
+
+// UnregisterAll unregisters every metric, stopping any that run a
+// background goroutine.
+func (r *StandardRegistry) UnregisterAll() {
+	r.metrics.Range(func(key, value interface{}) bool {
+		r.metrics.Delete(key)
+		stop(unwrapLazyMetric(value))
+		return true
+	})
+}
+
+func stop(metric interface{}) {
+	switch m := metric.(type) {
+	case Meter:
+		m.Stop()
+	case Timer:
+		m.Stop()
+	}
+}
+
+// lazyMetric defers constructing its metric until the first read, and uses
+// sync.Once so that concurrent GetOrRegister callers sharing the winning
+// lazyMetric (the one stored by LoadOrStore) only ever run the constructor
+// once between them.
+type lazyMetric struct {
+	once      sync.Once
+	construct func() interface{}
+	value     interface{}
+}
+
+func (l *lazyMetric) get() interface{} {
+	l.once.Do(func() { l.value = l.construct() })
+	return l.value
+}
+
+func constructorFor(i interface{}) func() interface{} {
+	if v := reflect.ValueOf(i); v.Kind() == reflect.Func {
+		return func() interface{} { return v.Call(nil)[0].Interface() }
+	}
+	return func() interface{} { return i }
+}
+
+func unwrapLazyMetric(item interface{}) interface{} {
+	if lazy, ok := item.(*lazyMetric); ok {
+		return lazy.get()
+	}
+	return item
+}
+
+// PrefixedRegistry wraps a Registry (possibly another PrefixedRegistry) and
+// prepends a prefix to every metric name it registers or looks up, so that
+// nested registries compose and concatenate their prefixes.
+type PrefixedRegistry struct {
+	underlying Registry
+	prefix     string
+}
+
+// NewPrefixedRegistry constructs a new PrefixedRegistry around a freshly
+// created StandardRegistry.
+func NewPrefixedRegistry(prefix string) Registry {
+	return &PrefixedRegistry{
+		underlying: NewRegistry(),
+		prefix:     prefix,
+	}
+}
+
+// NewPrefixedChildRegistry constructs a new PrefixedRegistry around an
+// existing parent Registry.
+func NewPrefixedChildRegistry(parent Registry, prefix string) Registry {
+	return &PrefixedRegistry{
+		underlying: parent,
+		prefix:     prefix,
+	}
+}
+
+// Each calls the given function for each metric registered under this
+// registry's prefix, walking up to the base registry so nested prefixes are
+// applied exactly once.
+func (r *PrefixedRegistry) Each(fn func(string, interface{})) {
+	baseRegistry, prefix := findPrefix(r, "")
+	baseRegistry.Each(func(name string, i interface{}) {
+		if strings.HasPrefix(name, prefix) {
+			fn(name, i)
+		}
+	})
+}
+
+// Get the metric by the given name or nil if none is registered.
+func (r *PrefixedRegistry) Get(name string) interface{} {
+	realName := r.prefix + name
+	return r.underlying.Get(realName)
+}
+
+// GetOrRegister gets an existing metric or registers the given one.
+func (r *PrefixedRegistry) GetOrRegister(name string, metric interface{}) interface{} {
+	realName := r.prefix + name
+	return r.underlying.GetOrRegister(realName, metric)
+}
+
+// Register the given metric under the given name.
+func (r *PrefixedRegistry) Register(name string, metric interface{}) error {
+	realName := r.prefix + name
+	return r.underlying.Register(realName, metric)
+}
+
+// Unregister the metric with the given name.
+func (r *PrefixedRegistry) Unregister(name string) {
+	realName := r.prefix + name
+	r.underlying.Unregister(realName)
+}
+
+// UnregisterAll unregisters every metric registered under this registry's
+// prefix, without touching metrics registered under sibling prefixes on a
+// shared parent.
+func (r *PrefixedRegistry) UnregisterAll() {
+	baseRegistry, prefix := findPrefix(r, "")
+	var names []string
+	baseRegistry.Each(func(name string, _ interface{}) {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	})
+	for _, name := range names {
+		baseRegistry.Unregister(name)
+	}
+}
+
+// RegisterCollector adds a Collector to the underlying registry. Names it
+// yields are not prefixed, and Each on this registry only yields names
+// that start with its prefix, so the Collector's metrics appear here only
+// if it emits names that already carry that prefix.
+func (r *PrefixedRegistry) RegisterCollector(c Collector) CollectorHandle {
+	return r.underlying.RegisterCollector(c)
+}
+
+// UnregisterCollector removes the Collector registration identified by
+// handle.
+func (r *PrefixedRegistry) UnregisterCollector(handle CollectorHandle) {
+	r.underlying.UnregisterCollector(handle)
+}
+
+// findPrefix walks up a chain of PrefixedRegistrys, accumulating their
+// prefixes, until it reaches the base Registry.
+func findPrefix(registry Registry, prefix string) (Registry, string) {
+	switch r := registry.(type) {
+	case *PrefixedRegistry:
+		return findPrefix(r.underlying, r.prefix+prefix)
+	default:
+		return r, prefix
+	}
+}
+
+// DefaultRegistry is the default registry used by the package-level
+// convenience functions below.
+var DefaultRegistry = NewRegistry()
+
+// Each calls the given function for each registered metric in the default
+// registry.
+func Each(f func(string, interface{})) {
+	DefaultRegistry.Each(f)
+}
+
+// Get the metric by the given name in the default registry, or nil if none
+// is registered.
+func Get(name string) interface{} {
+	return DefaultRegistry.Get(name)
+}
+
+// GetOrRegister gets an existing metric or registers the given one in the
+// default registry.
+func GetOrRegister(name string, i interface{}) interface{} {
+	return DefaultRegistry.GetOrRegister(name, i)
+}
+
+// Register the given metric under the given name in the default registry.
+func Register(name string, i interface{}) error {
+	return DefaultRegistry.Register(name, i)
+}
+
+// Unregister the metric with the given name from the default registry.
+func Unregister(name string) {
+	DefaultRegistry.Unregister(name)
+}
+
+// UnregisterAll unregisters every metric from the default registry.
+func UnregisterAll() {
+	DefaultRegistry.UnregisterAll()
+}
+
+// RegisterCollector adds a Collector to the default registry.
+func RegisterCollector(c Collector) CollectorHandle {
+	return DefaultRegistry.RegisterCollector(c)
+}
+
+// UnregisterCollector removes the Collector registration identified by
+// handle from the default registry.
+func UnregisterCollector(handle CollectorHandle) {
+	DefaultRegistry.UnregisterCollector(handle)
+}
+
+// UseNilMetrics is checked by the constructor functions for all of the
+// standard metrics. If it is true, the metric returned is a stub.
+//
+// This global kill-switch helps programs using the library to avoid the
+// overhead of metrics entirely when they do not wish to incur it.
+var UseNilMetrics bool = false
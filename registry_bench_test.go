@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// BenchmarkRegistryParallelHighCardinality exercises GetOrRegister with
+// 100k distinct names under contention, the workload the sync.Map-backed
+// StandardRegistry is meant to speed up relative to a single mutex guarding
+// a plain map.
+func BenchmarkRegistryParallelHighCardinality(b *testing.B) {
+	r := NewRegistry()
+	const n = 100000
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("metric%07d", i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			r.GetOrRegister(names[i%n], NewCounter)
+			i++
+		}
+	})
+}
+
+func TestRegistryUnregisterAll(t *testing.T) {
+	r := NewRegistry()
+	r.Register("foo", NewCounter())
+	r.Register("bar", NewMeter())
+	r.UnregisterAll()
+
+	i := 0
+	r.Each(func(string, interface{}) { i++ })
+	if i != 0 {
+		t.Fatal(i)
+	}
+}
+
+func TestRegistryRegisterDuplicateDoesNotConstruct(t *testing.T) {
+	r := NewRegistry()
+	r.Register("foo", NewCounter())
+
+	calls := 0
+	ctor := func() Counter {
+		calls++
+		return NewCounter()
+	}
+	if err := r.Register("foo", ctor); err == nil {
+		t.Fatal("expected DuplicateMetric error")
+	}
+	if calls != 0 {
+		t.Fatalf("expected constructor not to run on a duplicate Register, ran %d times", calls)
+	}
+}
+
+func TestRegistryGetOrRegisterLazyInstantiationOnlyOnce(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	ctor := func() Counter {
+		calls++
+		return NewCounter()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.GetOrRegister("foo", ctor)
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected constructor to run once, ran %d times", calls)
+	}
+}
@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ResettingTimer captures the duration and rate of events, exposing
+// windowed percentile snapshots that are cleared on every read. Unlike
+// Timer, it keeps every observed duration in a plain slice rather than a
+// sampling reservoir, so reporters that flush on a fixed interval (e.g.
+// InfluxDB, statsd) get an exact percentile snapshot of that interval
+// without keeping a sampling reservoir between flushes.
+type ResettingTimer interface {
+	Snapshot() ResettingTimerSnapshot
+	Time(func())
+	Update(int64)
+	UpdateSince(int64)
+	Values() []int64
+}
+
+// ResettingTimerSnapshot is a read-only view of the values recorded by a
+// ResettingTimer since the last snapshot was taken.
+type ResettingTimerSnapshot interface {
+	Count() int64
+	Max() int64
+	Mean() float64
+	Min() int64
+	Percentiles([]float64) []float64
+	Values() []int64
+}
+
+// GetOrRegisterResettingTimer returns an existing ResettingTimer or
+// constructs and registers a new StandardResettingTimer.
+func GetOrRegisterResettingTimer(name string, r Registry) ResettingTimer {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, NewResettingTimer).(ResettingTimer)
+}
+
+// NewResettingTimer constructs a new StandardResettingTimer.
+func NewResettingTimer() ResettingTimer {
+	if UseNilMetrics {
+		return NilResettingTimer{}
+	}
+	return &StandardResettingTimer{}
+}
+
+// NewRegisteredResettingTimer constructs and registers a new
+// StandardResettingTimer.
+func NewRegisteredResettingTimer(name string, r Registry) ResettingTimer {
+	c := NewResettingTimer()
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// resettingTimerSnapshot is the standard implementation of
+// ResettingTimerSnapshot, backed by the values recorded since the last
+// snapshot.
+type resettingTimerSnapshot struct {
+	values     []int64
+	mean       float64
+	min, max   int64
+	calculated bool
+}
+
+// NewResettingTimerSnapshot constructs a new ResettingTimerSnapshot from a
+// slice of durations.
+func NewResettingTimerSnapshot(values []int64) ResettingTimerSnapshot {
+	return &resettingTimerSnapshot{values: values}
+}
+
+// Values returns the values recorded at the time the snapshot was taken.
+func (t *resettingTimerSnapshot) Values() []int64 { return t.values }
+
+// Count returns the number of values recorded at the time the snapshot was
+// taken.
+func (t *resettingTimerSnapshot) Count() int64 {
+	return int64(len(t.values))
+}
+
+// Min returns the minimum value at the time the snapshot was taken.
+func (t *resettingTimerSnapshot) Min() int64 {
+	t.calc()
+	return t.min
+}
+
+// Max returns the maximum value at the time the snapshot was taken.
+func (t *resettingTimerSnapshot) Max() int64 {
+	t.calc()
+	return t.max
+}
+
+// Mean returns the mean value at the time the snapshot was taken.
+func (t *resettingTimerSnapshot) Mean() float64 {
+	t.calc()
+	return t.mean
+}
+
+// Percentiles returns the boundaries for the input percentiles, computed
+// by sorting the captured values on demand.
+func (t *resettingTimerSnapshot) Percentiles(percentiles []float64) []float64 {
+	t.calc()
+	return SamplePercentiles(t.values, percentiles)
+}
+
+func (t *resettingTimerSnapshot) calc() {
+	if t.calculated {
+		return
+	}
+	t.calculated = true
+	if len(t.values) == 0 {
+		return
+	}
+	sort.Sort(int64Slice(t.values))
+	t.min = t.values[0]
+	t.max = t.values[len(t.values)-1]
+	var sum int64
+	for _, v := range t.values {
+		sum += v
+	}
+	t.mean = float64(sum) / float64(len(t.values))
+}
+
+// NilResettingTimer is a no-op ResettingTimer.
+type NilResettingTimer struct{}
+
+// Snapshot returns a ResettingTimerSnapshot whose values are always zero.
+func (NilResettingTimer) Snapshot() ResettingTimerSnapshot {
+	return &resettingTimerSnapshot{}
+}
+
+// Time calls f without recording its duration.
+func (NilResettingTimer) Time(f func()) { f() }
+
+// Update is a no-op.
+func (NilResettingTimer) Update(int64) {}
+
+// UpdateSince is a no-op.
+func (NilResettingTimer) UpdateSince(int64) {}
+
+// Values always returns an empty slice.
+func (NilResettingTimer) Values() []int64 { return []int64{} }
+
+// StandardResettingTimer is the standard implementation of a
+// ResettingTimer. It records every duration into a plain slice and resets
+// that slice each time a snapshot is taken, so consecutive snapshots never
+// overlap.
+type StandardResettingTimer struct {
+	mutex  sync.Mutex
+	values []int64
+}
+
+// Snapshot returns a read-only copy of the values recorded since the last
+// snapshot, and resets the timer.
+func (t *StandardResettingTimer) Snapshot() ResettingTimerSnapshot {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	values := t.values
+	t.values = nil
+	return &resettingTimerSnapshot{values: values}
+}
+
+// Time records the duration of the execution of the given function.
+func (t *StandardResettingTimer) Time(f func()) {
+	ts := time.Now()
+	f()
+	t.Update(int64(time.Since(ts)))
+}
+
+// Update records a duration.
+func (t *StandardResettingTimer) Update(d int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.values = append(t.values, d)
+}
+
+// UpdateSince records the duration of an event that started at a UnixNano
+// timestamp and ends now.
+func (t *StandardResettingTimer) UpdateSince(ts int64) {
+	t.Update(time.Now().UnixNano() - ts)
+}
+
+// Values returns a copy of the values currently held by the timer, without
+// resetting it.
+func (t *StandardResettingTimer) Values() []int64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	values := make([]int64, len(t.values))
+	copy(values, t.values)
+	return values
+}
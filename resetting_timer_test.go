@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestResettingTimer(t *testing.T) {
+	tm := NewResettingTimer()
+	for i := 1; i <= 100; i++ {
+		tm.Update(int64(i))
+	}
+	snapshot := tm.Snapshot()
+
+	if length := len(snapshot.Values()); length != 100 {
+		t.Errorf("length mismatch: expected 100, got %v", length)
+	}
+
+	if empty := len(tm.Snapshot().Values()); empty != 0 {
+		t.Errorf("snapshot should reset the timer: expected 0, got %v", empty)
+	}
+
+	ps := snapshot.Percentiles([]float64{0.5, 0.75, 0.99})
+	if ps[0] < 49 || ps[0] > 51 || ps[1] < 74 || ps[1] > 76 || ps[2] < 98 || ps[2] > 100 {
+		t.Errorf("bad percentiles: %v", ps)
+	}
+
+	if min := snapshot.Min(); min != 1 {
+		t.Errorf("min mismatch: expected 1, got %v", min)
+	}
+
+	if max := snapshot.Max(); max != 100 {
+		t.Errorf("max mismatch: expected 100, got %v", max)
+	}
+
+	if mean := snapshot.Mean(); mean != 50.5 {
+		t.Errorf("mean mismatch: expected 50.5, got %v", mean)
+	}
+}
+
+func TestResettingTimerZero(t *testing.T) {
+	tm := NewResettingTimer()
+	snapshot := tm.Snapshot()
+
+	if count := snapshot.Count(); count != 0 {
+		t.Errorf("count mismatch: expected 0, got %v", count)
+	}
+	if min := snapshot.Min(); min != 0 {
+		t.Errorf("min mismatch: expected 0, got %v", min)
+	}
+	if max := snapshot.Max(); max != 0 {
+		t.Errorf("max mismatch: expected 0, got %v", max)
+	}
+}
+
+func TestNilResettingTimerSnapshot(t *testing.T) {
+	UseNilMetrics = true
+	defer func() { UseNilMetrics = false }()
+
+	tm := NewResettingTimer()
+	tm.Update(10)
+	snapshot := tm.Snapshot()
+
+	if count := snapshot.Count(); count != 0 {
+		t.Errorf("count mismatch: expected 0, got %v", count)
+	}
+	if ps := snapshot.Percentiles([]float64{0.5}); ps[0] != 0 {
+		t.Errorf("percentile mismatch: expected 0, got %v", ps[0])
+	}
+}
+
+func TestResettingTimerFromRegistry(t *testing.T) {
+	r := NewRegistry()
+	tm := GetOrRegisterResettingTimer("foo", r)
+	tm.Update(10)
+	r.Unregister("foo")
+
+	i := 0
+	r.Each(func(string, interface{}) { i++ })
+	if i != 0 {
+		t.Fatal(i)
+	}
+}
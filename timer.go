@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Timer captures the duration and rate of events. It is the write side of
+// the metric: call Update/UpdateSince/Time to record durations and
+// Snapshot to obtain an immutable view combining the underlying
+// Histogram's distribution with the underlying Meter's rates.
+type Timer interface {
+	Snapshot() TimerSnapshot
+	Stop()
+	Time(func())
+	Update(time.Duration)
+	UpdateSince(time.Time)
+}
+
+// TimerSnapshot is a read-only view of a Timer's distribution and rates at
+// the moment Snapshot() was called.
+type TimerSnapshot interface {
+	Count() int64
+	Max() int64
+	Mean() float64
+	Min() int64
+	Percentile(float64) float64
+	Percentiles([]float64) []float64
+	Rate1() float64
+	Rate5() float64
+	Rate15() float64
+	RateMean() float64
+	StdDev() float64
+	Sum() int64
+	Variance() float64
+}
+
+// GetOrRegisterTimer returns an existing Timer or constructs and registers a
+// new StandardTimer.
+func GetOrRegisterTimer(name string, r Registry) Timer {
+	if nil == r {
+		r = DefaultRegistry
+	}
+	return r.GetOrRegister(name, NewTimer).(Timer)
+}
+
+// NewTimer constructs a new StandardTimer using a uniform sample with a
+// reservoir size of 1028; older values are not decayed.
+func NewTimer() Timer {
+	if UseNilMetrics {
+		return NilTimer{}
+	}
+	return &StandardTimer{
+		histogram: NewHistogram(NewUniformSample(1028)),
+		meter:     NewMeter(),
+	}
+}
+
+// NewRegisteredTimer constructs and registers a new StandardTimer.
+func NewRegisteredTimer(name string, r Registry) Timer {
+	c := NewTimer()
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// timerSnapshot is the standard implementation of TimerSnapshot, backed by
+// a frozen HistogramSnapshot and MeterSnapshot taken together.
+type timerSnapshot struct {
+	histogram HistogramSnapshot
+	meter     MeterSnapshot
+}
+
+// Count returns the number of events recorded at the time the snapshot was
+// taken.
+func (t *timerSnapshot) Count() int64 { return t.histogram.Count() }
+
+// Max returns the maximum value at the time the snapshot was taken.
+func (t *timerSnapshot) Max() int64 { return t.histogram.Max() }
+
+// Mean returns the mean value at the time the snapshot was taken.
+func (t *timerSnapshot) Mean() float64 { return t.histogram.Mean() }
+
+// Min returns the minimum value at the time the snapshot was taken.
+func (t *timerSnapshot) Min() int64 { return t.histogram.Min() }
+
+// Percentile returns an arbitrary percentile of sampled values at the time
+// the snapshot was taken.
+func (t *timerSnapshot) Percentile(p float64) float64 {
+	return t.histogram.Percentile(p)
+}
+
+// Percentiles returns a slice of arbitrary percentiles of sampled values at
+// the time the snapshot was taken.
+func (t *timerSnapshot) Percentiles(ps []float64) []float64 {
+	return t.histogram.Percentiles(ps)
+}
+
+// Rate1 returns the one-minute moving average rate of events per second at
+// the time the snapshot was taken.
+func (t *timerSnapshot) Rate1() float64 { return t.meter.Rate1() }
+
+// Rate5 returns the five-minute moving average rate of events per second at
+// the time the snapshot was taken.
+func (t *timerSnapshot) Rate5() float64 { return t.meter.Rate5() }
+
+// Rate15 returns the fifteen-minute moving average rate of events per
+// second at the time the snapshot was taken.
+func (t *timerSnapshot) Rate15() float64 { return t.meter.Rate15() }
+
+// RateMean returns the meter's mean rate of events per second at the time
+// the snapshot was taken.
+func (t *timerSnapshot) RateMean() float64 { return t.meter.RateMean() }
+
+// StdDev returns the standard deviation of the values at the time the
+// snapshot was taken.
+func (t *timerSnapshot) StdDev() float64 { return t.histogram.StdDev() }
+
+// Sum returns the sum at the time the snapshot was taken.
+func (t *timerSnapshot) Sum() int64 { return t.histogram.Sum() }
+
+// Variance returns the variance of the values at the time the snapshot was
+// taken.
+func (t *timerSnapshot) Variance() float64 { return t.histogram.Variance() }
+
+// NilTimer is a no-op Timer.
+type NilTimer struct{}
+
+// Snapshot returns a TimerSnapshot whose values are always zero.
+func (NilTimer) Snapshot() TimerSnapshot {
+	return &timerSnapshot{
+		histogram: NilHistogram{}.Snapshot(),
+		meter:     NilMeter{}.Snapshot(),
+	}
+}
+
+// Stop is a no-op.
+func (NilTimer) Stop() {}
+
+// Time calls f without recording its duration.
+func (NilTimer) Time(f func()) { f() }
+
+// Update is a no-op.
+func (NilTimer) Update(d time.Duration) {}
+
+// UpdateSince is a no-op.
+func (NilTimer) UpdateSince(t time.Time) {}
+
+// StandardTimer is the standard implementation of a Timer and uses a
+// Histogram and Meter.
+type StandardTimer struct {
+	histogram Histogram
+	meter     Meter
+	mutex     sync.Mutex
+}
+
+// Snapshot returns a read-only copy of the timer, combining a single
+// Histogram snapshot with a single Meter snapshot.
+func (t *StandardTimer) Snapshot() TimerSnapshot {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return &timerSnapshot{
+		histogram: t.histogram.Snapshot(),
+		meter:     t.meter.Snapshot(),
+	}
+}
+
+// Stop stops the timer's underlying meter, Mark() will be a no-op for it
+// afterwards.
+func (t *StandardTimer) Stop() { t.meter.Stop() }
+
+// Record the duration of the execution of the given function.
+func (t *StandardTimer) Time(f func()) {
+	ts := time.Now()
+	f()
+	t.Update(time.Since(ts))
+}
+
+// Record the duration of an event.
+func (t *StandardTimer) Update(d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.histogram.Update(int64(d))
+	t.meter.Mark(1)
+}
+
+// Record the duration of an event that started at a time and ends now.
+func (t *StandardTimer) UpdateSince(ts time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.histogram.Update(int64(time.Since(ts)))
+	t.meter.Mark(1)
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import "testing"
+
+func TestTimerSnapshotIsConsistent(t *testing.T) {
+	tm := NewTimer()
+	tm.Update(10)
+	tm.Update(20)
+	tm.Update(30)
+
+	snapshot := tm.Snapshot()
+	if count := snapshot.Count(); count != 3 {
+		t.Fatal(count)
+	}
+	if min := snapshot.Min(); min != 10 {
+		t.Fatal(min)
+	}
+	if max := snapshot.Max(); max != 30 {
+		t.Fatal(max)
+	}
+
+	tm.Stop()
+}
+
+func TestMeterSnapshot(t *testing.T) {
+	m := NewMeter()
+	m.Mark(47)
+	if count := m.Snapshot().Count(); count != 47 {
+		t.Fatal(count)
+	}
+	m.Stop()
+}
+
+func TestHistogramSnapshot(t *testing.T) {
+	h := NewHistogram(NewUniformSample(100))
+	h.Update(1)
+	h.Update(2)
+	h.Update(3)
+
+	snapshot := h.Snapshot()
+	if count := snapshot.Count(); count != 3 {
+		t.Fatal(count)
+	}
+	if min := snapshot.Min(); min != 1 {
+		t.Fatal(min)
+	}
+	if max := snapshot.Max(); max != 3 {
+		t.Fatal(max)
+	}
+}
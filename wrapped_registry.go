@@ -0,0 +1,160 @@
+package metrics
+
+import "strings"
+
+// TaggedRegistry is implemented by registries, such as the ones returned by
+// WrapRegistererWith, that can associate a set of labels/tags with every
+// metric registered through them. Reporters that want to emit those labels
+// as tags/dimensions should type-assert a Registry to TaggedRegistry rather
+// than assuming all registries carry them.
+type TaggedRegistry interface {
+	Registry
+
+	// EachTagged calls the given function for each registered metric,
+	// along with the labels in effect for it. It behaves like Each except
+	// for the additional tags argument, so adopting it doesn't require
+	// changing existing Each-based callers.
+	EachTagged(func(name string, tags map[string]string, metric interface{}))
+
+	// Labels returns the labels that apply to the named metric.
+	Labels(name string) map[string]string
+}
+
+// WrapRegistererWith returns a Registry that prefixes nothing but attaches
+// the given labels to every metric registered through it. Wrapping an
+// already-wrapped or already-prefixed Registry composes: prefixes
+// concatenate and labels merge, with the outermost wrapper's labels taking
+// precedence on key collisions.
+func WrapRegistererWith(labels map[string]string, r Registry) Registry {
+	return &wrappedRegistry{underlying: r, labels: labels}
+}
+
+// WrapRegistererWithPrefix returns a Registry that behaves like one built
+// with NewPrefixedChildRegistry, but also implements TaggedRegistry so it
+// composes with WrapRegistererWith.
+func WrapRegistererWithPrefix(prefix string, r Registry) Registry {
+	return &wrappedRegistry{underlying: r, prefix: prefix}
+}
+
+// wrappedRegistry wraps a Registry (possibly another wrappedRegistry or a
+// PrefixedRegistry) adding a name prefix and/or a set of labels.
+type wrappedRegistry struct {
+	underlying Registry
+	prefix     string
+	labels     map[string]string
+}
+
+// Each calls the given function for each metric registered under this
+// wrapper's prefix. Labels are not surfaced here; use EachTagged for that.
+func (r *wrappedRegistry) Each(fn func(string, interface{})) {
+	baseRegistry, prefix, _ := findPrefixAndLabels(r, "", nil)
+	baseRegistry.Each(func(name string, i interface{}) {
+		if strings.HasPrefix(name, prefix) {
+			fn(name, i)
+		}
+	})
+}
+
+// EachTagged calls the given function for each metric registered under
+// this wrapper's prefix, along with the merged labels from this wrapper
+// and every wrapper it is nested in.
+func (r *wrappedRegistry) EachTagged(fn func(string, map[string]string, interface{})) {
+	baseRegistry, prefix, labels := findPrefixAndLabels(r, "", nil)
+	baseRegistry.Each(func(name string, i interface{}) {
+		if strings.HasPrefix(name, prefix) {
+			fn(name, labels, i)
+		}
+	})
+}
+
+// Get the metric by the given name or nil if none is registered.
+func (r *wrappedRegistry) Get(name string) interface{} {
+	return r.underlying.Get(r.prefix + name)
+}
+
+// GetOrRegister gets an existing metric or registers the given one.
+func (r *wrappedRegistry) GetOrRegister(name string, metric interface{}) interface{} {
+	return r.underlying.GetOrRegister(r.prefix+name, metric)
+}
+
+// Labels returns the labels in effect for the named metric, merged across
+// this wrapper and any wrapper it is nested in.
+func (r *wrappedRegistry) Labels(name string) map[string]string {
+	_, _, labels := findPrefixAndLabels(r, "", nil)
+	return labels
+}
+
+// Register the given metric under the given name.
+func (r *wrappedRegistry) Register(name string, metric interface{}) error {
+	return r.underlying.Register(r.prefix+name, metric)
+}
+
+// Unregister the metric with the given name.
+func (r *wrappedRegistry) Unregister(name string) {
+	r.underlying.Unregister(r.prefix + name)
+}
+
+// UnregisterAll unregisters every metric registered under this wrapper's
+// prefix, leaving metrics registered under sibling prefixes on a shared
+// parent untouched.
+func (r *wrappedRegistry) UnregisterAll() {
+	baseRegistry, prefix, _ := findPrefixAndLabels(r, "", nil)
+	var names []string
+	baseRegistry.Each(func(name string, _ interface{}) {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	})
+	for _, name := range names {
+		baseRegistry.Unregister(name)
+	}
+}
+
+// RegisterCollector adds a Collector to the underlying registry. Names it
+// yields are not prefixed, so Each and EachTagged on this wrapper yield
+// them only if they already start with the wrapper's prefix; EachTagged
+// then passes them with this wrapper's labels like any other metric.
+func (r *wrappedRegistry) RegisterCollector(c Collector) CollectorHandle {
+	return r.underlying.RegisterCollector(c)
+}
+
+// UnregisterCollector removes the Collector registration identified by
+// handle.
+func (r *wrappedRegistry) UnregisterCollector(handle CollectorHandle) {
+	r.underlying.UnregisterCollector(handle)
+}
+
+// findPrefixAndLabels walks up a chain of wrappedRegistrys and
+// PrefixedRegistrys, accumulating their prefixes and merging their labels,
+// until it reaches the base Registry. Labels set by registries closer to
+// where findPrefixAndLabels was called (the outer wrappers) win on key
+// collisions with registries further up the chain.
+func findPrefixAndLabels(registry Registry, prefix string, labels map[string]string) (Registry, string, map[string]string) {
+	switch r := registry.(type) {
+	case *wrappedRegistry:
+		return findPrefixAndLabels(r.underlying, r.prefix+prefix, mergeLabels(labels, r.labels))
+	case *PrefixedRegistry:
+		return findPrefixAndLabels(r.underlying, r.prefix+prefix, labels)
+	default:
+		return r, prefix, labels
+	}
+}
+
+// mergeLabels merges loser into winner, preferring winner's value on key
+// collisions. Either argument may be nil.
+func mergeLabels(winner, loser map[string]string) map[string]string {
+	if len(winner) == 0 {
+		return loser
+	}
+	if len(loser) == 0 {
+		return winner
+	}
+	merged := make(map[string]string, len(winner)+len(loser))
+	for k, v := range loser {
+		merged[k] = v
+	}
+	for k, v := range winner {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import "testing"
+
+func TestWrapRegistererWithLabels(t *testing.T) {
+	r := NewRegistry()
+	wr := WrapRegistererWith(map[string]string{"service": "api"}, r)
+
+	wr.Register("foo", NewCounter())
+
+	i := 0
+	wr.Each(func(name string, _ interface{}) {
+		i++
+		if name != "foo" {
+			t.Fatal(name)
+		}
+	})
+	if i != 1 {
+		t.Fatal(i)
+	}
+
+	labels := wr.(TaggedRegistry).Labels("foo")
+	if labels["service"] != "api" {
+		t.Fatalf("expected service=api, got %v", labels)
+	}
+
+	i = 0
+	wr.(TaggedRegistry).EachTagged(func(name string, tags map[string]string, _ interface{}) {
+		i++
+		if tags["service"] != "api" {
+			t.Fatalf("expected service=api, got %v", tags)
+		}
+	})
+	if i != 1 {
+		t.Fatal(i)
+	}
+}
+
+func TestWrapRegistererWithPrefixComposesWithPrefixedRegistry(t *testing.T) {
+	r := NewPrefixedChildRegistry(NewRegistry(), "prefix.")
+	wr := WrapRegistererWithPrefix("prefix2.", r)
+
+	wr.Register("baz", NewCounter())
+
+	i := 0
+	r.Each(func(name string, _ interface{}) {
+		i++
+		if name != "prefix.prefix2.baz" {
+			t.Fatal(name)
+		}
+	})
+	if i != 1 {
+		t.Fatal(i)
+	}
+}
+
+func TestWrapRegistererWithNestedLabelsMerge(t *testing.T) {
+	r := NewRegistry()
+	inner := WrapRegistererWith(map[string]string{"service": "api", "env": "prod"}, r)
+	outer := WrapRegistererWith(map[string]string{"env": "staging"}, inner)
+
+	outer.Register("foo", NewCounter())
+
+	labels := outer.(TaggedRegistry).Labels("foo")
+	if labels["service"] != "api" {
+		t.Fatalf("expected inherited service=api, got %v", labels)
+	}
+	if labels["env"] != "staging" {
+		t.Fatalf("expected outer env=staging to win, got %v", labels)
+	}
+}